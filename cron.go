@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a minimal 5-field ("minute hour day month weekday") cron
+// matcher, enough to drive the scrape scheduler without pulling in a
+// scheduling library for a single use site.
+type cronSchedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek fieldSet
+}
+
+// fieldSet is the set of values a cron field matches; nil means "any".
+type fieldSet map[int]bool
+
+// parseCron parses a standard 5-field cron expression.
+func parseCron(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields (minute hour day month weekday), got %d", len(fields))
+	}
+
+	minute, err := parseCronField(fields[0], 0, 59)
+	if err != nil {
+		return nil, fmt.Errorf("minute field: %w", err)
+	}
+	hour, err := parseCronField(fields[1], 0, 23)
+	if err != nil {
+		return nil, fmt.Errorf("hour field: %w", err)
+	}
+	dayOfMonth, err := parseCronField(fields[2], 1, 31)
+	if err != nil {
+		return nil, fmt.Errorf("day-of-month field: %w", err)
+	}
+	month, err := parseCronField(fields[3], 1, 12)
+	if err != nil {
+		return nil, fmt.Errorf("month field: %w", err)
+	}
+	dayOfWeek, err := parseCronField(fields[4], 0, 6)
+	if err != nil {
+		return nil, fmt.Errorf("day-of-week field: %w", err)
+	}
+
+	return &cronSchedule{
+		minute:     minute,
+		hour:       hour,
+		dayOfMonth: dayOfMonth,
+		month:      month,
+		dayOfWeek:  dayOfWeek,
+	}, nil
+}
+
+// parseCronField parses one cron field: "*", "*/N", "A,B,C", or "A-B".
+func parseCronField(field string, min, max int) (fieldSet, error) {
+	if field == "*" {
+		return nil, nil
+	}
+
+	set := fieldSet{}
+	for _, part := range strings.Split(field, ",") {
+		if step, ok := strings.CutPrefix(part, "*/"); ok {
+			n, err := strconv.Atoi(step)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid step %q", part)
+			}
+			for v := min; v <= max; v += n {
+				set[v] = true
+			}
+			continue
+		}
+
+		if lo, hi, ok := strings.Cut(part, "-"); ok {
+			loN, err1 := strconv.Atoi(lo)
+			hiN, err2 := strconv.Atoi(hi)
+			if err1 != nil || err2 != nil || loN > hiN {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+			for v := loN; v <= hiN; v++ {
+				set[v] = true
+			}
+			continue
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil || n < min || n > max {
+			return nil, fmt.Errorf("invalid value %q", part)
+		}
+		set[n] = true
+	}
+
+	return set, nil
+}
+
+func (f fieldSet) matches(v int) bool {
+	return f == nil || f[v]
+}
+
+// matches reports whether t falls within this schedule, at minute
+// resolution. Following POSIX cron, day-of-month and day-of-week are OR'd
+// together when both are restricted (non-"*"); when at most one is
+// restricted, matches() falls back to AND, which is also what "any" (nil)
+// on either field reduces to.
+func (c *cronSchedule) matches(t time.Time) bool {
+	if !c.minute.matches(t.Minute()) || !c.hour.matches(t.Hour()) || !c.month.matches(int(t.Month())) {
+		return false
+	}
+
+	if c.dayOfMonth != nil && c.dayOfWeek != nil {
+		return c.dayOfMonth.matches(t.Day()) || c.dayOfWeek.matches(int(t.Weekday()))
+	}
+	return c.dayOfMonth.matches(t.Day()) && c.dayOfWeek.matches(int(t.Weekday()))
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronField(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    string
+		min, max int
+		want     map[int]bool // nil means "any" (nil fieldSet)
+		wantErr  bool
+	}{
+		{name: "wildcard", field: "*", min: 0, max: 59, want: nil},
+		{name: "single value", field: "5", min: 0, max: 59, want: map[int]bool{5: true}},
+		{name: "list", field: "1,3,5", min: 0, max: 59, want: map[int]bool{1: true, 3: true, 5: true}},
+		{name: "range", field: "1-3", min: 0, max: 59, want: map[int]bool{1: true, 2: true, 3: true}},
+		{name: "step", field: "*/15", min: 0, max: 59, want: map[int]bool{0: true, 15: true, 30: true, 45: true}},
+		{name: "out of range", field: "60", min: 0, max: 59, wantErr: true},
+		{name: "invalid range", field: "5-1", min: 0, max: 59, wantErr: true},
+		{name: "invalid step", field: "*/0", min: 0, max: 59, wantErr: true},
+		{name: "garbage", field: "abc", min: 0, max: 59, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCronField(tt.field, tt.min, tt.max)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseCronField(%q) expected an error, got none", tt.field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCronField(%q) unexpected error: %v", tt.field, err)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("parseCronField(%q) = %v, want nil (any)", tt.field, got)
+				}
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseCronField(%q) = %v, want %v", tt.field, got, tt.want)
+			}
+			for v := range tt.want {
+				if !got[v] {
+					t.Errorf("parseCronField(%q) missing value %d", tt.field, v)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCronFieldCount(t *testing.T) {
+	fields := []string{"* * *", "* * * *", "* * * * * *"}
+	for _, expr := range fields {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q) expected an error for wrong field count", expr)
+		}
+	}
+}
+
+func TestCronScheduleMatchesBasic(t *testing.T) {
+	schedule, err := parseCron("30 9 * * *")
+	if err != nil {
+		t.Fatalf("parseCron: %v", err)
+	}
+
+	match := time.Date(2026, time.July, 26, 9, 30, 0, 0, time.UTC)
+	if !schedule.matches(match) {
+		t.Errorf("expected %v to match '30 9 * * *'", match)
+	}
+
+	noMatch := time.Date(2026, time.July, 26, 9, 31, 0, 0, time.UTC)
+	if schedule.matches(noMatch) {
+		t.Errorf("expected %v not to match '30 9 * * *'", noMatch)
+	}
+}
+
+// TestCronScheduleMatchesDayOfMonthOrDayOfWeek verifies the POSIX rule that
+// day-of-month and day-of-week are OR'd when both are restricted, instead
+// of AND'd.
+func TestCronScheduleMatchesDayOfMonthOrDayOfWeek(t *testing.T) {
+	schedule, err := parseCron("0 9 1 * 1")
+	if err != nil {
+		t.Fatalf("parseCron: %v", err)
+	}
+
+	// 2026-07-01 is a Wednesday: matches on day-of-month alone.
+	onFirst := time.Date(2026, time.July, 1, 9, 0, 0, 0, time.UTC)
+	if !schedule.matches(onFirst) {
+		t.Errorf("expected %v (the 1st) to match '0 9 1 * 1'", onFirst)
+	}
+
+	// 2026-07-06 is a Monday: matches on day-of-week alone.
+	aMonday := time.Date(2026, time.July, 6, 9, 0, 0, 0, time.UTC)
+	if !schedule.matches(aMonday) {
+		t.Errorf("expected %v (a Monday) to match '0 9 1 * 1'", aMonday)
+	}
+
+	// 2026-07-07 is a Tuesday, not the 1st: matches neither.
+	neither := time.Date(2026, time.July, 7, 9, 0, 0, 0, time.UTC)
+	if schedule.matches(neither) {
+		t.Errorf("expected %v to not match '0 9 1 * 1'", neither)
+	}
+}
+
+// TestCronScheduleMatchesDayOfMonthOnly verifies that when only one of
+// day-of-month/day-of-week is restricted, matches() still behaves as a
+// plain AND against the unrestricted ("*") field.
+func TestCronScheduleMatchesDayOfMonthOnly(t *testing.T) {
+	schedule, err := parseCron("0 9 15 * *")
+	if err != nil {
+		t.Fatalf("parseCron: %v", err)
+	}
+
+	onFifteenth := time.Date(2026, time.July, 15, 9, 0, 0, 0, time.UTC)
+	if !schedule.matches(onFifteenth) {
+		t.Errorf("expected %v to match '0 9 15 * *'", onFifteenth)
+	}
+
+	notFifteenth := time.Date(2026, time.July, 16, 9, 0, 0, 0, time.UTC)
+	if schedule.matches(notFifteenth) {
+		t.Errorf("expected %v to not match '0 9 15 * *'", notFifteenth)
+	}
+}
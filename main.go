@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"html"
@@ -10,6 +9,7 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -24,98 +24,59 @@ const (
 
 // Config holds the application configuration
 type Config struct {
-	APIKey        string
-	Port          string
-	DatabasePath  string
+	APIKey       string
+	Port         string
+	DatabasePath string
+	DBDriver     string
+	ScrapeCron   string
+	ScrapeURL    string
 }
 
-// Fund represents a structured fund entry
+// Fund represents a structured fund entry. Yearly returns (FY24, FY23, ...)
+// are kept in YearlyReturns rather than fixed fields so a new fiscal year
+// never requires a struct or schema change; see fund_yearly_returns.
 type Fund struct {
-	Name           string     `json:"name"`
-	LaunchDate     *time.Time `json:"launch_date,omitempty"`
-	ValidityDate   *time.Time `json:"validity_date,omitempty"`
-	Repurchase     *float64   `json:"repurchase,omitempty"`
-	Offer          *float64   `json:"offer,omitempty"`
-	NAV            *float64   `json:"nav,omitempty"`
-	MTD            *float64   `json:"mtd,omitempty"`
-	FYTD           *float64   `json:"fytd,omitempty"`
-	CYTD           *float64   `json:"cytd,omitempty"`
-	FY24           *float64   `json:"fy24,omitempty"`
-	FY23           *float64   `json:"fy23,omitempty"`
-	SinceInception *float64   `json:"since_inception,omitempty"`
-	UploadDate     time.Time  `json:"upload_date"`
+	Name           string             `json:"name"`
+	LaunchDate     *time.Time         `json:"launch_date,omitempty"`
+	ValidityDate   *time.Time         `json:"validity_date,omitempty"`
+	Repurchase     *float64           `json:"repurchase,omitempty"`
+	Offer          *float64           `json:"offer,omitempty"`
+	NAV            *float64           `json:"nav,omitempty"`
+	MTD            *float64           `json:"mtd,omitempty"`
+	FYTD           *float64           `json:"fytd,omitempty"`
+	CYTD           *float64           `json:"cytd,omitempty"`
+	YearlyReturns  map[string]float64 `json:"yearly_returns,omitempty"`
+	SinceInception *float64           `json:"since_inception,omitempty"`
+	UploadDate     time.Time          `json:"upload_date"`
+	SourceSHA256   *string            `json:"source_sha256,omitempty"`
 }
 
 // Server represents the HTTP server with its dependencies
 type Server struct {
-	config Config
-	logger *slog.Logger
-	db     *sql.DB
+	config     Config
+	logger     *slog.Logger
+	backend    Backend
+	httpClient *http.Client
 }
 
 // NewServer creates a new server instance
-func NewServer(config Config, logger *slog.Logger) (*Server, error) {
-	// Open SQLite database connection
-	db, err := sql.Open("sqlite3", config.DatabasePath)
+func NewServer(ctx context.Context, config Config, logger *slog.Logger) (*Server, error) {
+	backend, err := newBackend(ctx, config.DBDriver, config.DatabasePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
 
-	// Set connection parameters
-	db.SetMaxOpenConns(1)                  // SQLite only supports one writer at a time
-	db.SetMaxIdleConns(1)                  // Keep connection open
-	db.SetConnMaxLifetime(time.Hour * 24)  // Reasonable lifetime
-
-	// Create a server instance
-	server := &Server{
-		config: config,
-		logger: logger,
-		db:     db,
-	}
-
-	// Initialize database schema
-	if err := server.initDatabase(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to initialize database: %w", err)
-	}
-
-	return server, nil
-}
-
-// initDatabase creates the necessary tables if they don't exist
-func (s *Server) initDatabase() error {
-	// Create funds table
-	createTableSQL := `
-	CREATE TABLE IF NOT EXISTS funds (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL,
-		launch_date TEXT,
-		validity_date TEXT,
-		repurchase REAL,
-		offer REAL,
-		nav REAL,
-		mtd REAL,
-		fytd REAL,
-		cytd REAL,
-		fy24 REAL,
-		fy23 REAL,
-		since_inception REAL,
-		upload_date TEXT NOT NULL
-	);
-	CREATE INDEX IF NOT EXISTS idx_fund_name ON funds(name);
-	CREATE INDEX IF NOT EXISTS idx_upload_date ON funds(upload_date);
-	`
-
-	_, err := s.db.Exec(createTableSQL)
-	if err != nil {
-		return fmt.Errorf("failed to create tables: %w", err)
-	}
-	return nil
+	return &Server{
+		config:     config,
+		logger:     logger,
+		backend:    backend,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
 }
 
 // Close closes the database connection
 func (s *Server) Close() error {
-	return s.db.Close()
+	return s.backend.Close()
 }
 
 // parseFloat safely parses a string to a float, returning nil if invalid
@@ -160,6 +121,16 @@ func parseDate(date string) *time.Time {
 	return nil
 }
 
+// truncateToDate drops the time-of-day component of t, keeping only the
+// calendar date (UTC midnight). upload_date is keyed at day resolution
+// (UNIQUE(name, upload_date)), so every code path that defaults it to "now"
+// needs to agree on that resolution or same-day re-ingestion stops
+// correcting the existing row and starts duplicating it instead.
+func truncateToDate(t time.Time) time.Time {
+	truncated, _ := time.Parse("2006-01-02", t.Format("2006-01-02"))
+	return truncated
+}
+
 // parseHTML extracts fund information from HTML content
 func parseHTML(ctx context.Context, logger *slog.Logger, htmlContent string, uploadDate time.Time) ([]Fund, error) {
 	var funds []Fund
@@ -172,6 +143,8 @@ func parseHTML(ctx context.Context, logger *slog.Logger, htmlContent string, upl
 
 	logger.InfoContext(ctx, "starting HTML parsing")
 
+	yearlyReturnColumns := yearlyReturnColumnLabels(doc)
+
 	// Iterate over each row in the table
 	doc.Find("tr[align='center']").Each(func(index int, row *goquery.Selection) {
 		columns := row.Find("td")
@@ -185,6 +158,13 @@ func parseHTML(ctx context.Context, logger *slog.Logger, htmlContent string, upl
 			return strings.TrimSpace(columns.Eq(idx).Text())
 		}
 
+		yearlyReturns := map[string]float64{}
+		for i, fiscalYear := range yearlyReturnColumns {
+			if v := parseFloat(cleanText(9 + i)); v != nil {
+				yearlyReturns[fiscalYear] = *v
+			}
+		}
+
 		fund := Fund{
 			Name:           strings.TrimRight(cleanText(0), "*"),
 			LaunchDate:     parseDate(cleanText(1)),
@@ -195,8 +175,7 @@ func parseHTML(ctx context.Context, logger *slog.Logger, htmlContent string, upl
 			MTD:            parseFloat(cleanText(6)),
 			FYTD:           parseFloat(cleanText(7)),
 			CYTD:           parseFloat(cleanText(8)),
-			FY24:           parseFloat(cleanText(9)),
-			FY23:           parseFloat(cleanText(10)),
+			YearlyReturns:  yearlyReturns,
 			SinceInception: parseFloat(cleanText(11)),
 			UploadDate:     uploadDate,
 		}
@@ -209,63 +188,43 @@ func parseHTML(ctx context.Context, logger *slog.Logger, htmlContent string, upl
 	return funds, nil
 }
 
-// storeFunds saves fund data to the SQLite database
-func (s *Server) storeFunds(ctx context.Context, funds []Fund) error {
-	// Begin a transaction
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback() // Rollback if not committed
-
-	// Prepare the insert statement
-	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO funds (
-			name, launch_date, validity_date, repurchase, offer, nav, 
-			mtd, fytd, cytd, fy24, fy23, since_inception, upload_date
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
-	}
-	defer stmt.Close()
-
-	// Helper function to format date pointers
-	formatDate := func(t *time.Time) interface{} {
-		if t == nil {
-			return nil
+// fiscalYearHeaderPattern matches header cells like "FY24" or "FY23".
+var fiscalYearHeaderPattern = regexp.MustCompile(`^FY\d{2}$`)
+
+// yearlyReturnColumnLabels inspects the table header row to find the
+// fiscal-year labels for the two yearly-return columns (positions 9 and
+// 10), so a future FY25/FY26 column is picked up without a code change.
+// It falls back to the historical FY24/FY23 labels if no header row with
+// two FY-shaped cells can be found.
+func yearlyReturnColumnLabels(doc *goquery.Document) []string {
+	var labels []string
+	doc.Find("tr").EachWithBreak(func(_ int, row *goquery.Selection) bool {
+		headers := row.Find("th")
+		if headers.Length() < 11 {
+			return true // keep looking for the header row
 		}
-		return t.Format(time.RFC3339)
-	}
-
-	// Insert each fund
-	for _, fund := range funds {
-		_, err := stmt.ExecContext(ctx,
-			fund.Name,
-			formatDate(fund.LaunchDate),
-			formatDate(fund.ValidityDate),
-			fund.Repurchase,
-			fund.Offer,
-			fund.NAV,
-			fund.MTD,
-			fund.FYTD,
-			fund.CYTD,
-			fund.FY24,
-			fund.FY23,
-			fund.SinceInception,
-			fund.UploadDate.Format(time.RFC3339),
-		)
-		if err != nil {
-			return fmt.Errorf("failed to insert fund '%s': %w", fund.Name, err)
+
+		var found []string
+		headers.Each(func(i int, cell *goquery.Selection) {
+			if i != 9 && i != 10 {
+				return
+			}
+			text := strings.TrimSpace(cell.Text())
+			if fiscalYearHeaderPattern.MatchString(text) {
+				found = append(found, text)
+			}
+		})
+		if len(found) == 2 {
+			labels = found
+			return false // found a usable header row, stop
 		}
-	}
+		return true
+	})
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	if len(labels) != 2 {
+		return []string{"FY24", "FY23"}
 	}
-
-	return nil
+	return labels
 }
 
 // uploadHandler processes the HTML file upload and returns structured data
@@ -313,8 +272,11 @@ func (s *Server) uploadHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	// Get the date parameter from the form, default to current time if not provided
-	uploadDate := time.Now()
+	// Get the date parameter from the form, default to today if not provided.
+	// Truncated to a calendar date (no time-of-day) so it lines up with the
+	// UNIQUE(name, upload_date) constraint: re-uploading the same day's file
+	// corrects that day's row instead of inserting a new one.
+	uploadDate := truncateToDate(time.Now().UTC())
 	if dateStr := r.FormValue("date"); dateStr != "" {
 		parsedDate, err := time.Parse("2006-01-02", dateStr)
 		if err != nil {
@@ -326,7 +288,7 @@ func (s *Server) uploadHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	logger = logger.With(
-		"filename", fileHeader.Filename, 
+		"filename", fileHeader.Filename,
 		"filesize", fileHeader.Size,
 		"upload_date", uploadDate.Format("2006-01-02"),
 	)
@@ -353,10 +315,30 @@ func (s *Server) uploadHandler(w http.ResponseWriter, r *http.Request) {
 	// Decode HTML entities
 	htmlContent := html.UnescapeString(string(fileBytes))
 
+	// Content-address the decoded upload so re-uploading the same file is a
+	// no-op instead of creating duplicate history.
+	sha256Hex := sha256Hex(htmlContent)
+	logger = logger.With("sha256", sha256Hex)
+
+	existing, err := s.uploadBySHA256(ctx, sha256Hex)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to check for duplicate upload", "error", err)
+		http.Error(w, "Failed to check for duplicate upload", http.StatusInternalServerError)
+		return
+	}
+	if existing != nil {
+		logger.WarnContext(ctx, "duplicate upload rejected")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": "duplicate upload", "sha256": sha256Hex})
+		return
+	}
+
 	// Parse HTML to extract fund details
 	funds, err := parseHTML(ctx, logger, htmlContent, uploadDate)
 	if err != nil {
 		logger.ErrorContext(ctx, "failed to parse HTML content", "error", err)
+		s.recordParseFailure(ctx, "upload", err.Error())
 		http.Error(w, "Failed to parse HTML: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -367,13 +349,28 @@ func (s *Server) uploadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := s.storeUpload(ctx, sha256Hex, fileHeader.Filename, int64(len(fileBytes)), uploadDate, htmlContent, "upload"); err != nil {
+		logger.ErrorContext(ctx, "failed to store upload blob", "error", err)
+		http.Error(w, "Failed to store upload blob", http.StatusInternalServerError)
+		return
+	}
+	for i := range funds {
+		funds[i].SourceSHA256 = &sha256Hex
+	}
+
+	// Capture each fund's previous values before the upsert overwrites them,
+	// so the webhook event below can report what moved.
+	previous := s.loadPreviousFundValues(ctx, funds)
+
 	// Store the funds in the database
-	if err := s.storeFunds(ctx, funds); err != nil {
+	if err := s.backend.StoreFunds(ctx, funds); err != nil {
 		logger.ErrorContext(ctx, "failed to store funds in database", "error", err)
 		http.Error(w, "Failed to store funds in database: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	s.publishFundsIngested(ctx, uploadDate, sha256Hex, funds, previous)
+
 	// Encode response as JSON
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(funds); err != nil {
@@ -382,7 +379,7 @@ func (s *Server) uploadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logger.InfoContext(ctx, "request completed successfully", 
+	logger.InfoContext(ctx, "request completed successfully",
 		"fund_count", len(funds),
 		"stored_in_db", true,
 	)
@@ -393,7 +390,7 @@ func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Test database connection
-	if err := s.db.PingContext(ctx); err != nil {
+	if err := s.backend.Ping(ctx); err != nil {
 		s.logger.ErrorContext(ctx, "database health check failed", "error", err)
 		http.Error(w, "Database connection failed", http.StatusServiceUnavailable)
 		return
@@ -414,6 +411,9 @@ func main() {
 		APIKey:       getEnv("API_KEY", "your-secret-api-key"),
 		Port:         getEnv("PORT", "8089"),
 		DatabasePath: getEnv("DB_PATH", "./funds.db"),
+		DBDriver:     getEnv("DB_DRIVER", "sqlite"),
+		ScrapeCron:   getEnv("SCRAPE_CRON", ""),
+		ScrapeURL:    getEnv("SCRAPE_URL", ""),
 	}
 
 	// Validate config
@@ -421,23 +421,64 @@ func main() {
 		logger.Warn("using default API key, consider setting API_KEY environment variable")
 	}
 
+	migrateOnly := false
+	for _, arg := range os.Args[1:] {
+		if arg == "--migrate-only" {
+			migrateOnly = true
+		}
+	}
+
+	ctx := context.Background()
+
 	// Create server
-	server, err := NewServer(config, logger)
+	server, err := NewServer(ctx, config, logger)
 	if err != nil {
 		logger.Error("failed to initialize server", "error", err)
 		os.Exit(1)
 	}
 	defer server.Close()
 
+	if migrateOnly {
+		logger.Info("migrations applied, exiting due to --migrate-only")
+		return
+	}
+
+	dispatcher := newWebhookDispatcher(server, logger)
+	go dispatcher.Run(ctx)
+
+	if config.ScrapeCron != "" && config.ScrapeURL != "" {
+		sched, err := newScheduler(server, logger, config.ScrapeCron, config.ScrapeURL)
+		if err != nil {
+			logger.Error("failed to start scheduler", "error", err)
+			os.Exit(1)
+		}
+		go sched.Run(ctx)
+	} else {
+		logger.Info("scraper scheduler disabled; set SCRAPE_CRON and SCRAPE_URL to enable")
+	}
+
 	// Register handlers
 	http.HandleFunc("/upload", server.uploadHandler)
 	http.HandleFunc("/health", server.healthHandler)
+	http.HandleFunc("/metrics", server.metricsHandler)
+
+	http.HandleFunc("/jobs", server.jobsHandler)
+	http.HandleFunc("/jobs/", server.jobsSubrouteHandler)
+
+	http.HandleFunc("/uploads", server.uploadsHandler)
+	http.HandleFunc("/uploads/", server.uploadsSubrouteHandler)
+
+	http.HandleFunc("/funds", server.fundsHandler)
+	http.HandleFunc("/funds/", server.fundsSubrouteHandler)
+
+	http.HandleFunc("/webhooks", server.registerWebhookHandler)
 
 	// Start server
 	addr := ":" + config.Port
-	logger.Info("server starting", 
+	logger.Info("server starting",
 		"port", config.Port,
 		"database", config.DatabasePath,
+		"db_driver", config.DBDriver,
 	)
 
 	// Use server with timeout handling
@@ -460,4 +501,4 @@ func getEnv(key, fallback string) string {
 		return value
 	}
 	return fallback
-}
\ No newline at end of file
+}
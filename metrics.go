@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// metricsHandler exposes per-fund gauges (sourced from each fund's most
+// recent row) plus ingestion counters, in Prometheus text exposition
+// format, so Grafana/alerting can consume the dataset without a separate
+// ETL step.
+func (s *Server) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Header.Get("X-API-Key") != s.config.APIKey {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	rows, err := s.backend.DB().QueryContext(ctx, `
+		SELECT f.name, f.nav, f.mtd, f.since_inception
+		FROM funds f
+		INNER JOIN (
+			SELECT name, MAX(upload_date) AS upload_date FROM funds GROUP BY name
+		) latest ON latest.name = f.name AND latest.upload_date = f.upload_date
+	`)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query latest funds for metrics", "error", err)
+		http.Error(w, "Failed to query metrics", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	var b strings.Builder
+	b.WriteString("# HELP meezan_fund_nav Most recent net asset value per fund.\n")
+	b.WriteString("# TYPE meezan_fund_nav gauge\n")
+	b.WriteString("# HELP meezan_fund_mtd Most recent month-to-date return per fund.\n")
+	b.WriteString("# TYPE meezan_fund_mtd gauge\n")
+	b.WriteString("# HELP meezan_fund_since_inception Most recent since-inception return per fund.\n")
+	b.WriteString("# TYPE meezan_fund_since_inception gauge\n")
+
+	for rows.Next() {
+		var name string
+		var nav, mtd, sinceInception *float64
+		if err := rows.Scan(&name, &nav, &mtd, &sinceInception); err != nil {
+			s.logger.ErrorContext(ctx, "failed to scan fund metrics row", "error", err)
+			continue
+		}
+		label := fmt.Sprintf("name=%q", name)
+		writeGauge(&b, "meezan_fund_nav", label, nav)
+		writeGauge(&b, "meezan_fund_mtd", label, mtd)
+		writeGauge(&b, "meezan_fund_since_inception", label, sinceInception)
+	}
+
+	b.WriteString("# HELP meezan_uploads_processed_total Uploads successfully processed.\n")
+	b.WriteString("# TYPE meezan_uploads_processed_total counter\n")
+	writeCounter(&b, "meezan_uploads_processed_total", s.countRows(ctx, "uploads"))
+
+	b.WriteString("# HELP meezan_parse_failures_total Parse or fetch failures across scheduled scrapes and manual uploads.\n")
+	b.WriteString("# TYPE meezan_parse_failures_total counter\n")
+	parseFailures := s.countRowsWhere(ctx, "scrape_jobs", "status = 'failed'") + s.countRows(ctx, "parse_failures")
+	writeCounter(&b, "meezan_parse_failures_total", parseFailures)
+
+	b.WriteString("# HELP meezan_fund_rows_inserted_total Fund rows currently stored; an upsert over an existing row adds nothing, and rows removed by a reparse drop out of the count.\n")
+	b.WriteString("# TYPE meezan_fund_rows_inserted_total counter\n")
+	writeCounter(&b, "meezan_fund_rows_inserted_total", s.countRows(ctx, "funds"))
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write([]byte(b.String()))
+}
+
+func writeGauge(b *strings.Builder, name, labels string, value *float64) {
+	if value == nil {
+		return
+	}
+	fmt.Fprintf(b, "%s{%s} %g\n", name, labels, *value)
+}
+
+func writeCounter(b *strings.Builder, name string, value int64) {
+	fmt.Fprintf(b, "%s %d\n", name, value)
+}
+
+// recordParseFailure logs a parse/fetch failure outside scrape_jobs, which
+// only scheduled scrapes write to, so meezan_parse_failures_total also
+// covers failures from the manual /upload path.
+func (s *Server) recordParseFailure(ctx context.Context, source, errMsg string) {
+	_, err := s.backend.DB().ExecContext(ctx, placeholderQuery(s.backend.Driver(), `
+		INSERT INTO parse_failures (source, error, created_at) VALUES (?, ?, ?)
+	`), source, errMsg, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to record parse failure", "error", err)
+	}
+}
+
+// countRows and countRowsWhere back the /metrics counters; table names are
+// always literals from this file, never caller input.
+func (s *Server) countRows(ctx context.Context, table string) int64 {
+	return s.countRowsWhere(ctx, table, "1=1")
+}
+
+func (s *Server) countRowsWhere(ctx context.Context, table, where string) int64 {
+	var count int64
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", table, where)
+	if err := s.backend.DB().QueryRowContext(ctx, query).Scan(&count); err != nil {
+		s.logger.ErrorContext(ctx, "failed to count rows for metrics", "error", err, "table", table)
+		return 0
+	}
+	return count
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"time"
+)
+
+//go:embed migrations/sqlite/*.sql
+var sqliteMigrations embed.FS
+
+//go:embed migrations/postgres/*.sql
+var postgresMigrations embed.FS
+
+// migrationsFor returns the embedded migration filesystem for a driver.
+func migrationsFor(driver string) (embed.FS, string, error) {
+	switch driver {
+	case "sqlite", "sqlite3":
+		return sqliteMigrations, "migrations/sqlite", nil
+	case "postgres":
+		return postgresMigrations, "migrations/postgres", nil
+	default:
+		return embed.FS{}, "", fmt.Errorf("unsupported DB_DRIVER %q", driver)
+	}
+}
+
+// runMigrations applies every embedded .sql file that hasn't been recorded
+// in schema_migrations yet, in filename order, each inside its own
+// transaction. Files are named NNNN_description.sql; NNNN becomes the
+// migration's version number.
+func runMigrations(ctx context.Context, db *sql.DB, driver string) error {
+	migrationsFS, dir, err := migrationsFor(driver)
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			applied_at TEXT NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	entries, err := fs.ReadDir(migrationsFS, dir)
+	if err != nil {
+		return fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	applied := map[int]bool{}
+	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan migration version: %w", err)
+		}
+		applied[version] = true
+	}
+	rows.Close()
+
+	for _, entry := range entries {
+		version, name, err := parseMigrationFilename(entry.Name())
+		if err != nil {
+			return err
+		}
+		if applied[version] {
+			continue
+		}
+
+		contents, err := fs.ReadFile(migrationsFS, dir+"/"+entry.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration transaction for %s: %w", name, err)
+		}
+		if _, err := tx.ExecContext(ctx, string(contents)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %s: %w", name, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			placeholderQuery(driver, "INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)"),
+			version, time.Now().UTC().Format(time.RFC3339),
+		); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %s: %w", name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// parseMigrationFilename splits "0001_init.sql" into its version number and
+// base name, used both for ordering and for schema_migrations bookkeeping.
+func parseMigrationFilename(filename string) (int, string, error) {
+	var version int
+	if _, err := fmt.Sscanf(filename, "%04d_", &version); err != nil {
+		return 0, "", fmt.Errorf("migration filename %q doesn't start with a 4-digit version", filename)
+	}
+	return version, filename, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"io/fs"
+	"sort"
+	"testing"
+)
+
+func TestParseMigrationFilename(t *testing.T) {
+	version, name, err := parseMigrationFilename("0003_yearly_returns.sql")
+	if err != nil {
+		t.Fatalf("parseMigrationFilename: %v", err)
+	}
+	if version != 3 {
+		t.Errorf("version = %d, want 3", version)
+	}
+	if name != "0003_yearly_returns.sql" {
+		t.Errorf("name = %q, want %q", name, "0003_yearly_returns.sql")
+	}
+
+	if _, _, err := parseMigrationFilename("init.sql"); err == nil {
+		t.Error("parseMigrationFilename(\"init.sql\") expected an error, got none")
+	}
+}
+
+// TestMigrationsOrderAndVersions checks the embedded migration sets for both
+// drivers: filenames sort into strictly increasing, unique version numbers,
+// so runMigrations applies them in the intended order exactly once each.
+func TestMigrationsOrderAndVersions(t *testing.T) {
+	for _, driver := range []string{"sqlite", "postgres"} {
+		t.Run(driver, func(t *testing.T) {
+			migrationsFS, dir, err := migrationsFor(driver)
+			if err != nil {
+				t.Fatalf("migrationsFor(%q): %v", driver, err)
+			}
+
+			entries, err := fs.ReadDir(migrationsFS, dir)
+			if err != nil {
+				t.Fatalf("ReadDir(%q): %v", dir, err)
+			}
+			if len(entries) == 0 {
+				t.Fatalf("no migrations embedded for driver %q", driver)
+			}
+			sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+			lastVersion := -1
+			for _, entry := range entries {
+				version, _, err := parseMigrationFilename(entry.Name())
+				if err != nil {
+					t.Fatalf("parseMigrationFilename(%q): %v", entry.Name(), err)
+				}
+				if version <= lastVersion {
+					t.Fatalf("migration %q out of order: version %d did not increase past %d", entry.Name(), version, lastVersion)
+				}
+				lastVersion = version
+			}
+		})
+	}
+}
+
+func TestMigrationsForUnsupportedDriver(t *testing.T) {
+	if _, _, err := migrationsFor("mysql"); err == nil {
+		t.Error("migrationsFor(\"mysql\") expected an error, got none")
+	}
+}
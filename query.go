@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fundsHandler serves GET /funds, filtered by name/from/to/limit.
+func (s *Server) fundsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-API-Key") != s.config.APIKey {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := parseFundsQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	funds, err := s.backend.QueryFunds(r.Context(), filter)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to query funds", "error", err)
+		http.Error(w, "Failed to query funds", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(funds)
+}
+
+// fundsSubrouteHandler serves GET /funds/export, GET /funds/{name}/history
+// and GET /funds/{name}/latest.
+func (s *Server) fundsSubrouteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-API-Key") != s.config.APIKey {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/funds/")
+	if path == "export" {
+		s.fundsExportHandler(w, r)
+		return
+	}
+
+	parts := strings.SplitN(path, "/", 2)
+	name := parts[0]
+	if name == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	filter := QueryFilter{Name: name}
+	if len(parts) == 2 && parts[1] == "latest" {
+		filter.Limit = 1
+	} else if len(parts) != 2 || parts[1] != "history" {
+		http.NotFound(w, r)
+		return
+	}
+
+	funds, err := s.backend.QueryFunds(r.Context(), filter)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to query fund history", "error", err, "name", name)
+		http.Error(w, "Failed to query fund history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if filter.Limit == 1 {
+		if len(funds) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(funds[0])
+		return
+	}
+	json.NewEncoder(w).Encode(funds)
+}
+
+// fundsExportHandler serves GET /funds/export?format=csv|jsonl for bulk
+// download of the filtered result set.
+func (s *Server) fundsExportHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFundsQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	funds, err := s.backend.QueryFunds(r.Context(), filter)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to query funds for export", "error", err)
+		http.Error(w, "Failed to query funds", http.StatusInternalServerError)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "jsonl":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		encoder := json.NewEncoder(w)
+		for _, fund := range funds {
+			if err := encoder.Encode(fund); err != nil {
+				return
+			}
+		}
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"name", "upload_date", "nav", "mtd", "fytd", "cytd", "since_inception"})
+		for _, fund := range funds {
+			writer.Write([]string{
+				fund.Name,
+				fund.UploadDate.Format("2006-01-02"),
+				formatFloatPtr(fund.NAV),
+				formatFloatPtr(fund.MTD),
+				formatFloatPtr(fund.FYTD),
+				formatFloatPtr(fund.CYTD),
+				formatFloatPtr(fund.SinceInception),
+			})
+		}
+		writer.Flush()
+	default:
+		http.Error(w, "Unsupported format, use csv or jsonl", http.StatusBadRequest)
+	}
+}
+
+// parseFundsQuery builds a QueryFilter from the name/from/to/limit query
+// parameters shared by /funds and /funds/export.
+func parseFundsQuery(r *http.Request) (QueryFilter, error) {
+	q := r.URL.Query()
+	filter := QueryFilter{Name: q.Get("name")}
+
+	if from := q.Get("from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return QueryFilter{}, fmt.Errorf("invalid 'from' date, expected YYYY-MM-DD")
+		}
+		filter.From = t
+	}
+	if to := q.Get("to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return QueryFilter{}, fmt.Errorf("invalid 'to' date, expected YYYY-MM-DD")
+		}
+		filter.To = t
+	}
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return QueryFilter{}, fmt.Errorf("invalid 'limit', expected a positive integer")
+		}
+		filter.Limit = n
+	}
+	if offset := q.Get("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			return QueryFilter{}, fmt.Errorf("invalid 'offset', expected a non-negative integer")
+		}
+		filter.Offset = n
+		if filter.Limit == 0 {
+			filter.Limit = defaultFundsPageSize
+		}
+	}
+
+	return filter, nil
+}
+
+// defaultFundsPageSize caps /funds pages when a caller paginates with
+// 'offset' but doesn't specify 'limit'.
+const defaultFundsPageSize = 100
+
+func formatFloatPtr(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScrapeJob records one run of the scheduled (or manually triggered)
+// ingestion, mirroring the upload-status bookkeeping pattern already used
+// for HTML uploads.
+type ScrapeJob struct {
+	ID           int64      `json:"id"`
+	Status       string     `json:"status"` // running, success, failed; a job is stored as running from the start, never as pending
+	FetchedBytes int64      `json:"fetched_bytes,omitempty"`
+	SHA256       string     `json:"sha256,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+const (
+	scrapeJobStatusRunning = "running"
+	scrapeJobStatusSuccess = "success"
+	scrapeJobStatusFailed  = "failed"
+)
+
+// scheduler fetches the configured Al-Meezan fund-prices page on a cron
+// schedule and feeds it through the same parseHTML/StoreFunds pipeline the
+// /upload endpoint uses.
+type scheduler struct {
+	server   *Server
+	logger   *slog.Logger
+	schedule *cronSchedule
+	url      string
+}
+
+// newScheduler parses cronExpr and returns a scheduler ready to Run.
+func newScheduler(server *Server, logger *slog.Logger, cronExpr, url string) (*scheduler, error) {
+	schedule, err := parseCron(cronExpr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCRAPE_CRON: %w", err)
+	}
+	return &scheduler{
+		server:   server,
+		logger:   logger,
+		schedule: schedule,
+		url:      url,
+	}, nil
+}
+
+// Run checks the schedule once a minute until ctx is done, triggering a
+// scrape each time the current minute matches.
+func (sc *scheduler) Run(ctx context.Context) {
+	sc.logger.InfoContext(ctx, "scraper scheduler started", "url", sc.url)
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if !sc.schedule.matches(now) {
+				continue
+			}
+			if _, err := sc.RunOnce(ctx); err != nil {
+				sc.logger.ErrorContext(ctx, "scheduled scrape failed", "error", err)
+			}
+		}
+	}
+}
+
+// RunOnce fetches the configured URL, parses it through the existing
+// pipeline, and records the outcome as a scrape_jobs row. A page whose
+// SHA-256 matches an already-stored upload (scraped or uploaded by hand) is
+// skipped so an unchanged page doesn't insert another set of rows for the
+// same date.
+func (sc *scheduler) RunOnce(ctx context.Context) (*ScrapeJob, error) {
+	return sc.server.runScrape(ctx, sc.logger, sc.url)
+}
+
+// runScrape is the shared implementation behind both the scheduler and the
+// manually-triggered /jobs/run endpoint; both reuse the server's pooled
+// HTTP client rather than dialing a fresh connection per scrape.
+func (s *Server) runScrape(ctx context.Context, logger *slog.Logger, url string) (*ScrapeJob, error) {
+	job := &ScrapeJob{Status: scrapeJobStatusRunning, StartedAt: time.Now().UTC()}
+	jobID, err := s.insertScrapeJob(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	job.ID = jobID
+
+	finish := func(status, errMsg string, fetchedBytes int64, sha256Hex string) {
+		finishedAt := time.Now().UTC()
+		job.Status = status
+		job.Error = errMsg
+		job.FetchedBytes = fetchedBytes
+		job.SHA256 = sha256Hex
+		job.FinishedAt = &finishedAt
+		if err := s.updateScrapeJob(ctx, job); err != nil {
+			logger.ErrorContext(ctx, "failed to record scrape job outcome", "error", err, "job_id", job.ID)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		finish(scrapeJobStatusFailed, err.Error(), 0, "")
+		return job, fmt.Errorf("failed to build scrape request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		finish(scrapeJobStatusFailed, err.Error(), 0, "")
+		return job, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFileSize))
+	if err != nil {
+		finish(scrapeJobStatusFailed, err.Error(), 0, "")
+		return job, fmt.Errorf("failed to read scrape response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		errMsg := fmt.Sprintf("unexpected status %d", resp.StatusCode)
+		finish(scrapeJobStatusFailed, errMsg, int64(len(body)), "")
+		return job, fmt.Errorf("failed to scrape %s: %s", url, errMsg)
+	}
+
+	htmlContent := html.UnescapeString(string(body))
+	sha256Hex := sha256Hex(htmlContent)
+
+	if existing, err := s.uploadBySHA256(ctx, sha256Hex); err != nil {
+		finish(scrapeJobStatusFailed, err.Error(), int64(len(body)), sha256Hex)
+		return job, fmt.Errorf("failed to check for duplicate scrape: %w", err)
+	} else if existing != nil {
+		logger.InfoContext(ctx, "scraped page unchanged, skipping ingestion", "sha256", sha256Hex)
+		finish(scrapeJobStatusSuccess, "", int64(len(body)), sha256Hex)
+		return job, nil
+	}
+
+	uploadDate := truncateToDate(time.Now().UTC())
+	funds, err := parseHTML(ctx, logger, htmlContent, uploadDate)
+	if err != nil {
+		finish(scrapeJobStatusFailed, err.Error(), int64(len(body)), sha256Hex)
+		return job, fmt.Errorf("failed to parse scraped page: %w", err)
+	}
+
+	if err := s.storeUpload(ctx, sha256Hex, "scrape-"+uploadDate.Format("2006-01-02")+".html", int64(len(body)), uploadDate, htmlContent, "scrape"); err != nil {
+		finish(scrapeJobStatusFailed, err.Error(), int64(len(body)), sha256Hex)
+		return job, fmt.Errorf("failed to store scraped blob: %w", err)
+	}
+	for i := range funds {
+		funds[i].SourceSHA256 = &sha256Hex
+	}
+
+	previous := s.loadPreviousFundValues(ctx, funds)
+	if err := s.backend.StoreFunds(ctx, funds); err != nil {
+		finish(scrapeJobStatusFailed, err.Error(), int64(len(body)), sha256Hex)
+		return job, fmt.Errorf("failed to store scraped funds: %w", err)
+	}
+
+	s.publishFundsIngested(ctx, uploadDate, sha256Hex, funds, previous)
+
+	finish(scrapeJobStatusSuccess, "", int64(len(body)), sha256Hex)
+	logger.InfoContext(ctx, "scrape completed", "fund_count", len(funds), "sha256", sha256Hex)
+	return job, nil
+}
+
+func (s *Server) insertScrapeJob(ctx context.Context, job *ScrapeJob) (int64, error) {
+	row := s.backend.DB().QueryRowContext(ctx, placeholderQuery(s.backend.Driver(), `
+		INSERT INTO scrape_jobs (status, started_at) VALUES (?, ?) RETURNING id
+	`), job.Status, job.StartedAt.Format(time.RFC3339))
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to insert scrape job: %w", err)
+	}
+	return id, nil
+}
+
+func (s *Server) updateScrapeJob(ctx context.Context, job *ScrapeJob) error {
+	var finishedAt interface{}
+	if job.FinishedAt != nil {
+		finishedAt = job.FinishedAt.Format(time.RFC3339)
+	}
+	_, err := s.backend.DB().ExecContext(ctx, placeholderQuery(s.backend.Driver(), `
+		UPDATE scrape_jobs SET status = ?, fetched_bytes = ?, sha256 = ?, error = ?, finished_at = ? WHERE id = ?
+	`), job.Status, job.FetchedBytes, job.SHA256, job.Error, finishedAt, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update scrape job: %w", err)
+	}
+	return nil
+}
+
+func scanScrapeJob(row interface{ Scan(...interface{}) error }) (*ScrapeJob, error) {
+	var job ScrapeJob
+	var sha256, errMsg sql.NullString
+	var fetchedBytes sql.NullInt64
+	var startedAt string
+	var finishedAt sql.NullString
+
+	if err := row.Scan(&job.ID, &job.Status, &fetchedBytes, &sha256, &errMsg, &startedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+	job.FetchedBytes = fetchedBytes.Int64
+	job.SHA256 = sha256.String
+	job.Error = errMsg.String
+	job.StartedAt, _ = time.Parse(time.RFC3339, startedAt)
+	if finishedAt.Valid {
+		if t, err := time.Parse(time.RFC3339, finishedAt.String); err == nil {
+			job.FinishedAt = &t
+		}
+	}
+	return &job, nil
+}
+
+// jobsHandler lists scrape jobs, newest first.
+func (s *Server) jobsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Header.Get("X-API-Key") != s.config.APIKey {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rows, err := s.backend.DB().QueryContext(ctx,
+		"SELECT id, status, fetched_bytes, sha256, error, started_at, finished_at FROM scrape_jobs ORDER BY started_at DESC")
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list scrape jobs", "error", err)
+		http.Error(w, "Failed to list scrape jobs", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	var jobs []ScrapeJob
+	for rows.Next() {
+		job, err := scanScrapeJob(rows)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to scan scrape job", "error", err)
+			http.Error(w, "Failed to list scrape jobs", http.StatusInternalServerError)
+			return
+		}
+		jobs = append(jobs, *job)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jobs)
+}
+
+// jobsSubrouteHandler serves GET /jobs/{id} and POST /jobs/run.
+func (s *Server) jobsSubrouteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Header.Get("X-API-Key") != s.config.APIKey {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	if path == "run" {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if s.config.ScrapeURL == "" {
+			http.Error(w, "SCRAPE_URL is not configured", http.StatusPreconditionFailed)
+			return
+		}
+		job, err := s.runScrape(ctx, s.logger, s.config.ScrapeURL)
+		if err != nil && job == nil {
+			http.Error(w, "Failed to trigger scrape: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(job)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(path, 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	row := s.backend.DB().QueryRowContext(ctx, placeholderQuery(s.backend.Driver(),
+		"SELECT id, status, fetched_bytes, sha256, error, started_at, finished_at FROM scrape_jobs WHERE id = ?"), id)
+	job, err := scanScrapeJob(row)
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to load scrape job", "error", err, "job_id", id)
+		http.Error(w, "Failed to load scrape job", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
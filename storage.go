@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq" // Postgres driver
+)
+
+// QueryFilter narrows a QueryFunds call to a name, a time range, and a page.
+type QueryFilter struct {
+	Name   string
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
+}
+
+// Backend is the storage abstraction the rest of the server codes against,
+// so SQLite and Postgres can be swapped via DB_DRIVER without touching
+// handler code. Subsystems that need tables beyond `funds` (uploads, scrape
+// jobs, webhooks) use DB() directly, the same way the original code talked
+// to *sql.DB.
+type Backend interface {
+	StoreFunds(ctx context.Context, funds []Fund) error
+	QueryFunds(ctx context.Context, filter QueryFilter) ([]Fund, error)
+	Ping(ctx context.Context) error
+	Close() error
+	DB() *sql.DB
+	Driver() string
+}
+
+// sqlBackend implements Backend for both drivers; the only differences
+// between SQLite and Postgres are placeholder syntax and the open/init
+// steps, both handled by dialect-aware helpers.
+type sqlBackend struct {
+	db     *sql.DB
+	driver string
+}
+
+// newBackend opens a database connection for the configured driver, applies
+// migrations, and returns the resulting Backend.
+func newBackend(ctx context.Context, driver, dsn string) (Backend, error) {
+	switch driver {
+	case "sqlite", "sqlite3":
+		driver = "sqlite3"
+	case "postgres":
+		driver = "postgres"
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q", driver)
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if driver == "sqlite3" {
+		// SQLite only supports one writer at a time.
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+	} else {
+		db.SetMaxOpenConns(10)
+		db.SetMaxIdleConns(10)
+	}
+	db.SetConnMaxLifetime(time.Hour * 24)
+
+	normalized := driver
+	if normalized == "sqlite3" {
+		normalized = "sqlite"
+	}
+	if err := runMigrations(ctx, db, normalized); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	return &sqlBackend{db: db, driver: normalized}, nil
+}
+
+func (b *sqlBackend) DB() *sql.DB { return b.db }
+
+func (b *sqlBackend) Driver() string { return b.driver }
+
+func (b *sqlBackend) Ping(ctx context.Context) error {
+	return b.db.PingContext(ctx)
+}
+
+func (b *sqlBackend) Close() error {
+	return b.db.Close()
+}
+
+// placeholderQuery rewrites "?" placeholders into "$1", "$2", ... for
+// Postgres; SQLite and MySQL-style "?" placeholders pass through unchanged.
+func placeholderQuery(driver, query string) string {
+	if driver != "postgres" {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// StoreFunds upserts each fund keyed on (name, upload_date), so re-ingesting
+// a day's file corrects values instead of inserting duplicate rows, and
+// writes its yearly-return figures into fund_yearly_returns.
+func (b *sqlBackend) StoreFunds(ctx context.Context, funds []Fund) error {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	upsertFund := placeholderQuery(b.driver, `
+		INSERT INTO funds (
+			name, launch_date, validity_date, repurchase, offer, nav,
+			mtd, fytd, cytd, since_inception, upload_date, source_sha256
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name, upload_date) DO UPDATE SET
+			launch_date = excluded.launch_date,
+			validity_date = excluded.validity_date,
+			repurchase = excluded.repurchase,
+			offer = excluded.offer,
+			nav = excluded.nav,
+			mtd = excluded.mtd,
+			fytd = excluded.fytd,
+			cytd = excluded.cytd,
+			since_inception = excluded.since_inception,
+			source_sha256 = excluded.source_sha256
+		RETURNING id
+	`)
+	upsertYearlyReturn := placeholderQuery(b.driver, `
+		INSERT INTO fund_yearly_returns (fund_id, fiscal_year, value)
+		VALUES (?, ?, ?)
+		ON CONFLICT (fund_id, fiscal_year) DO UPDATE SET value = excluded.value
+	`)
+
+	formatDate := func(t *time.Time) interface{} {
+		if t == nil {
+			return nil
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	for _, fund := range funds {
+		var fundID int64
+		err := tx.QueryRowContext(ctx, upsertFund,
+			fund.Name,
+			formatDate(fund.LaunchDate),
+			formatDate(fund.ValidityDate),
+			fund.Repurchase,
+			fund.Offer,
+			fund.NAV,
+			fund.MTD,
+			fund.FYTD,
+			fund.CYTD,
+			fund.SinceInception,
+			fund.UploadDate.Format(time.RFC3339),
+			fund.SourceSHA256,
+		).Scan(&fundID)
+		if err != nil {
+			return fmt.Errorf("failed to upsert fund '%s': %w", fund.Name, err)
+		}
+
+		for fiscalYear, value := range fund.YearlyReturns {
+			if _, err := tx.ExecContext(ctx, upsertYearlyReturn, fundID, fiscalYear, value); err != nil {
+				return fmt.Errorf("failed to upsert %s return for fund '%s': %w", fiscalYear, fund.Name, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// QueryFunds returns fund rows matching the filter, newest upload first,
+// with each row's yearly returns attached from fund_yearly_returns.
+func (b *sqlBackend) QueryFunds(ctx context.Context, filter QueryFilter) ([]Fund, error) {
+	query := `SELECT id, name, launch_date, validity_date, repurchase, offer, nav, mtd, fytd, cytd, since_inception, upload_date, source_sha256 FROM funds WHERE 1=1`
+	var args []interface{}
+
+	if filter.Name != "" {
+		query += " AND name = ?"
+		args = append(args, filter.Name)
+	}
+	if !filter.From.IsZero() {
+		query += " AND upload_date >= ?"
+		args = append(args, filter.From.Format(time.RFC3339))
+	}
+	if !filter.To.IsZero() {
+		query += " AND upload_date <= ?"
+		args = append(args, filter.To.Format(time.RFC3339))
+	}
+	query += " ORDER BY upload_date DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query += " OFFSET ?"
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := b.db.QueryContext(ctx, placeholderQuery(b.driver, query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query funds: %w", err)
+	}
+	defer rows.Close()
+
+	var funds []Fund
+	var ids []int64
+	for rows.Next() {
+		var (
+			id                          int64
+			launchDate, validityDate    sql.NullString
+			repurchase, offer, nav      sql.NullFloat64
+			mtd, fytd, cytd, sinceIncep sql.NullFloat64
+			uploadDate                  string
+			sourceSHA256                sql.NullString
+			fund                        Fund
+		)
+		if err := rows.Scan(&id, &fund.Name, &launchDate, &validityDate, &repurchase, &offer, &nav,
+			&mtd, &fytd, &cytd, &sinceIncep, &uploadDate, &sourceSHA256); err != nil {
+			return nil, fmt.Errorf("failed to scan fund row: %w", err)
+		}
+
+		fund.LaunchDate = nullStringToDate(launchDate)
+		fund.ValidityDate = nullStringToDate(validityDate)
+		fund.Repurchase = nullFloatToPtr(repurchase)
+		fund.Offer = nullFloatToPtr(offer)
+		fund.NAV = nullFloatToPtr(nav)
+		fund.MTD = nullFloatToPtr(mtd)
+		fund.FYTD = nullFloatToPtr(fytd)
+		fund.CYTD = nullFloatToPtr(cytd)
+		fund.SinceInception = nullFloatToPtr(sinceIncep)
+		if sourceSHA256.Valid {
+			fund.SourceSHA256 = &sourceSHA256.String
+		}
+		if parsed, err := time.Parse(time.RFC3339, uploadDate); err == nil {
+			fund.UploadDate = parsed
+		}
+
+		funds = append(funds, fund)
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate fund rows: %w", err)
+	}
+
+	// Build the id->*Fund index only once the slice is done growing, so
+	// later appends can't reallocate out from under these pointers.
+	byID := make(map[int64]*Fund, len(funds))
+	for i, id := range ids {
+		byID[id] = &funds[i]
+	}
+
+	if err := attachYearlyReturns(ctx, b.db, b.driver, ids, byID); err != nil {
+		return nil, err
+	}
+
+	return funds, nil
+}
+
+// attachYearlyReturns fills in each fund's YearlyReturns map from
+// fund_yearly_returns, keyed by the fund IDs already loaded by the caller.
+func attachYearlyReturns(ctx context.Context, db *sql.DB, driver string, ids []int64, byID map[int64]*Fund) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	query := placeholderQuery(driver, fmt.Sprintf(
+		"SELECT fund_id, fiscal_year, value FROM fund_yearly_returns WHERE fund_id IN (%s)",
+		strings.Join(placeholders, ", "),
+	))
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query yearly returns: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var fundID int64
+		var fiscalYear string
+		var value sql.NullFloat64
+		if err := rows.Scan(&fundID, &fiscalYear, &value); err != nil {
+			return fmt.Errorf("failed to scan yearly return row: %w", err)
+		}
+		if !value.Valid {
+			continue
+		}
+		fund, ok := byID[fundID]
+		if !ok {
+			continue
+		}
+		if fund.YearlyReturns == nil {
+			fund.YearlyReturns = map[string]float64{}
+		}
+		fund.YearlyReturns[fiscalYear] = value.Float64
+	}
+	return rows.Err()
+}
+
+func nullStringToDate(ns sql.NullString) *time.Time {
+	if !ns.Valid {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, ns.String)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func nullFloatToPtr(nf sql.NullFloat64) *float64 {
+	if !nf.Valid {
+		return nil
+	}
+	v := nf.Float64
+	return &v
+}
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestPlaceholderQuery(t *testing.T) {
+	tests := []struct {
+		name, driver, query, want string
+	}{
+		{
+			name:   "sqlite passthrough",
+			driver: "sqlite",
+			query:  "SELECT * FROM funds WHERE name = ? AND upload_date = ?",
+			want:   "SELECT * FROM funds WHERE name = ? AND upload_date = ?",
+		},
+		{
+			name:   "postgres rewrite",
+			driver: "postgres",
+			query:  "SELECT * FROM funds WHERE name = ? AND upload_date = ?",
+			want:   "SELECT * FROM funds WHERE name = $1 AND upload_date = $2",
+		},
+		{
+			name:   "postgres no placeholders",
+			driver: "postgres",
+			query:  "SELECT * FROM funds",
+			want:   "SELECT * FROM funds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := placeholderQuery(tt.driver, tt.query); got != tt.want {
+				t.Errorf("placeholderQuery(%q, %q) = %q, want %q", tt.driver, tt.query, got, tt.want)
+			}
+		})
+	}
+}
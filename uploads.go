@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Upload describes a stored raw HTML blob, content-addressed by the
+// SHA-256 of its decoded contents.
+type Upload struct {
+	SHA256     string    `json:"sha256"`
+	Filename   string    `json:"filename"`
+	Size       int64     `json:"size"`
+	UploadedAt time.Time `json:"uploaded_at"`
+	UploadDate time.Time `json:"upload_date"`
+	Source     string    `json:"source"`
+}
+
+// sha256Hex returns the hex-encoded SHA-256 of the given content.
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// uploadBySHA256 returns the stored upload metadata for a hash, or nil if
+// no upload with that hash exists yet.
+func (s *Server) uploadBySHA256(ctx context.Context, sha256Hex string) (*Upload, error) {
+	row := s.backend.DB().QueryRowContext(ctx, placeholderQuery(s.backend.Driver(),
+		"SELECT sha256, filename, size, uploaded_at, upload_date, source FROM uploads WHERE sha256 = ?"),
+		sha256Hex,
+	)
+
+	var upload Upload
+	var uploadedAt, uploadDate string
+	if err := row.Scan(&upload.SHA256, &upload.Filename, &upload.Size, &uploadedAt, &uploadDate, &upload.Source); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query upload: %w", err)
+	}
+	upload.UploadedAt, _ = time.Parse(time.RFC3339, uploadedAt)
+	upload.UploadDate, _ = time.Parse(time.RFC3339, uploadDate)
+	return &upload, nil
+}
+
+// storeUpload persists the original HTML blob for an ingestion, keyed by
+// its SHA-256, so it can be listed, downloaded, or reparsed later.
+func (s *Server) storeUpload(ctx context.Context, sha256Hex, filename string, size int64, uploadDate time.Time, content, source string) error {
+	_, err := s.backend.DB().ExecContext(ctx, placeholderQuery(s.backend.Driver(), `
+		INSERT INTO uploads (sha256, filename, size, uploaded_at, upload_date, source, html)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`), sha256Hex, filename, size, time.Now().UTC().Format(time.RFC3339), uploadDate.Format(time.RFC3339), source, content)
+	if err != nil {
+		return fmt.Errorf("failed to store upload: %w", err)
+	}
+	return nil
+}
+
+// uploadsHandler lists stored upload blobs, newest first.
+func (s *Server) uploadsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Header.Get("X-API-Key") != s.config.APIKey {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rows, err := s.backend.DB().QueryContext(ctx,
+		"SELECT sha256, filename, size, uploaded_at, upload_date, source FROM uploads ORDER BY uploaded_at DESC")
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list uploads", "error", err)
+		http.Error(w, "Failed to list uploads", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	var uploads []Upload
+	for rows.Next() {
+		var upload Upload
+		var uploadedAt, uploadDate string
+		if err := rows.Scan(&upload.SHA256, &upload.Filename, &upload.Size, &uploadedAt, &uploadDate, &upload.Source); err != nil {
+			s.logger.ErrorContext(ctx, "failed to scan upload row", "error", err)
+			http.Error(w, "Failed to list uploads", http.StatusInternalServerError)
+			return
+		}
+		upload.UploadedAt, _ = time.Parse(time.RFC3339, uploadedAt)
+		upload.UploadDate, _ = time.Parse(time.RFC3339, uploadDate)
+		uploads = append(uploads, upload)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(uploads)
+}
+
+// uploadsSubrouteHandler serves GET /uploads/{sha} (download the original
+// HTML) and POST /uploads/{sha}/reparse (re-run parseHTML and replace the
+// fund rows it produced).
+func (s *Server) uploadsSubrouteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Header.Get("X-API-Key") != s.config.APIKey {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/uploads/")
+	parts := strings.SplitN(path, "/", 2)
+	sha256Hex := parts[0]
+	if sha256Hex == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "reparse" {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.reparseUploadHandler(w, r, sha256Hex)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var content string
+	var filename string
+	err := s.backend.DB().QueryRowContext(ctx, placeholderQuery(s.backend.Driver(),
+		"SELECT filename, html FROM uploads WHERE sha256 = ?"), sha256Hex,
+	).Scan(&filename, &content)
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to load upload", "error", err, "sha256", sha256Hex)
+		http.Error(w, "Failed to load upload", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	fmt.Fprint(w, content)
+}
+
+// reparseUploadHandler re-runs parseHTML against a stored blob and
+// replaces the fund rows it previously produced, so history can be
+// rebuilt after a parser fix without re-uploading the original file.
+func (s *Server) reparseUploadHandler(w http.ResponseWriter, r *http.Request, sha256Hex string) {
+	ctx := r.Context()
+	logger := s.logger.With("sha256", sha256Hex)
+
+	var content, uploadDateStr string
+	err := s.backend.DB().QueryRowContext(ctx, placeholderQuery(s.backend.Driver(),
+		"SELECT html, upload_date FROM uploads WHERE sha256 = ?"), sha256Hex,
+	).Scan(&content, &uploadDateStr)
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to load upload for reparse", "error", err)
+		http.Error(w, "Failed to load upload", http.StatusInternalServerError)
+		return
+	}
+
+	uploadDate, err := time.Parse(time.RFC3339, uploadDateStr)
+	if err != nil {
+		logger.ErrorContext(ctx, "stored upload has invalid upload_date", "error", err)
+		http.Error(w, "Stored upload has invalid upload_date", http.StatusInternalServerError)
+		return
+	}
+
+	funds, err := parseHTML(ctx, logger, content, uploadDate)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to reparse upload", "error", err)
+		http.Error(w, "Failed to reparse upload: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for i := range funds {
+		funds[i].SourceSHA256 = &sha256Hex
+	}
+
+	if err := s.backend.StoreFunds(ctx, funds); err != nil {
+		logger.ErrorContext(ctx, "failed to store reparsed funds", "error", err)
+		http.Error(w, "Failed to store reparsed funds", http.StatusInternalServerError)
+		return
+	}
+
+	names := make([]string, len(funds))
+	for i, fund := range funds {
+		names[i] = fund.Name
+	}
+	if err := s.deleteStaleFundsBySHA(ctx, sha256Hex, names); err != nil {
+		logger.ErrorContext(ctx, "failed to remove stale fund rows after reparse", "error", err)
+		http.Error(w, "Failed to remove stale fund rows", http.StatusInternalServerError)
+		return
+	}
+
+	logger.InfoContext(ctx, "reparsed upload", "fund_count", len(funds))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(funds)
+}
+
+// deleteStaleFundsBySHA removes fund rows still tied to sha256Hex whose name
+// isn't in keepNames, so a fund that disappeared from the source page (or a
+// parser fix that drops a row) doesn't linger after a reparse.
+func (s *Server) deleteStaleFundsBySHA(ctx context.Context, sha256Hex string, keepNames []string) error {
+	if len(keepNames) == 0 {
+		_, err := s.backend.DB().ExecContext(ctx, placeholderQuery(s.backend.Driver(),
+			"DELETE FROM funds WHERE source_sha256 = ?"), sha256Hex)
+		if err != nil {
+			return fmt.Errorf("failed to delete stale funds: %w", err)
+		}
+		return nil
+	}
+
+	placeholders := make([]string, len(keepNames))
+	args := make([]interface{}, 0, len(keepNames)+1)
+	args = append(args, sha256Hex)
+	for i, name := range keepNames {
+		placeholders[i] = "?"
+		args = append(args, name)
+	}
+
+	query := placeholderQuery(s.backend.Driver(), fmt.Sprintf(
+		"DELETE FROM funds WHERE source_sha256 = ? AND name NOT IN (%s)",
+		strings.Join(placeholders, ", "),
+	))
+	if _, err := s.backend.DB().ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete stale funds: %w", err)
+	}
+	return nil
+}
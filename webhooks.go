@@ -0,0 +1,344 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// fundIngestedEvent is published after a successful StoreFunds so
+// downstream bots/dashboards can react to new NAVs without polling the DB.
+type fundIngestedEvent struct {
+	Event      string      `json:"event"`
+	UploadDate time.Time   `json:"upload_date"`
+	SHA256     string      `json:"sha256"`
+	FundCount  int         `json:"fund_count"`
+	Funds      []Fund      `json:"funds"`
+	Deltas     []fundDelta `json:"deltas,omitempty"`
+}
+
+// fundDelta captures how a fund's key figures moved vs the row it replaced,
+// so a subscriber can tell what changed without fetching history itself.
+type fundDelta struct {
+	Name                string   `json:"name"`
+	NAVDelta            *float64 `json:"nav_delta,omitempty"`
+	MTDDelta            *float64 `json:"mtd_delta,omitempty"`
+	FYTDDelta           *float64 `json:"fytd_delta,omitempty"`
+	CYTDDelta           *float64 `json:"cytd_delta,omitempty"`
+	SinceInceptionDelta *float64 `json:"since_inception_delta,omitempty"`
+}
+
+// Webhook is a registered HTTP subscriber for fund.ingested events.
+type Webhook struct {
+	ID         int64  `json:"id"`
+	URL        string `json:"url"`
+	Active     bool   `json:"active"`
+	LastStatus int    `json:"last_status,omitempty"`
+	LastError  string `json:"last_error,omitempty"`
+	Retries    int    `json:"retries"`
+}
+
+const (
+	webhookMaxRetries  = 8
+	webhookRetryBase   = 30 * time.Second
+	webhookDeliverTick = 15 * time.Second
+)
+
+// webhook_deliveries.status values. "failed" is a distinct terminal state
+// from "delivered" so a delivery that gave up after webhookMaxRetries
+// attempts can't be mistaken for one the subscriber actually acknowledged.
+const (
+	webhookDeliveryPending   = "pending"
+	webhookDeliveryDelivered = "delivered"
+	webhookDeliveryFailed    = "failed"
+)
+
+// webhookDispatcher delivers pending webhook_deliveries rows with
+// exponential backoff; the queue is persisted in the configured database
+// (SQLite or Postgres) so events survive restarts instead of living only in
+// memory.
+type webhookDispatcher struct {
+	server *Server
+	logger *slog.Logger
+	client *http.Client
+}
+
+func newWebhookDispatcher(server *Server, logger *slog.Logger) *webhookDispatcher {
+	return &webhookDispatcher{server: server, logger: logger, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Run polls for due deliveries until ctx is done.
+func (d *webhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookDeliverTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.deliverDue(ctx)
+		}
+	}
+}
+
+// loadPreviousFundValues looks up each fund's currently-stored (name,
+// upload_date) row before StoreFunds overwrites it, so publishFundsIngested
+// can diff old vs new once the upsert has completed. A fund with no prior
+// row (first ingestion for that name/date) is simply absent from the map.
+func (s *Server) loadPreviousFundValues(ctx context.Context, funds []Fund) map[string]Fund {
+	previous := make(map[string]Fund, len(funds))
+	for _, fund := range funds {
+		row := s.backend.DB().QueryRowContext(ctx, placeholderQuery(s.backend.Driver(), `
+			SELECT nav, mtd, fytd, cytd, since_inception FROM funds WHERE name = ? AND upload_date = ?
+		`), fund.Name, fund.UploadDate.Format(time.RFC3339))
+
+		var nav, mtd, fytd, cytd, sinceIncep sql.NullFloat64
+		if err := row.Scan(&nav, &mtd, &fytd, &cytd, &sinceIncep); err != nil {
+			continue
+		}
+		previous[fund.Name] = Fund{
+			NAV:            nullFloatToPtr(nav),
+			MTD:            nullFloatToPtr(mtd),
+			FYTD:           nullFloatToPtr(fytd),
+			CYTD:           nullFloatToPtr(cytd),
+			SinceInception: nullFloatToPtr(sinceIncep),
+		}
+	}
+	return previous
+}
+
+// buildFundDeltas diffs funds against the previous-row values
+// loadPreviousFundValues captured before the upsert. Funds with no prior
+// row (and so nothing to diff against) are omitted.
+func buildFundDeltas(funds []Fund, previous map[string]Fund) []fundDelta {
+	var deltas []fundDelta
+	for _, fund := range funds {
+		prev, ok := previous[fund.Name]
+		if !ok {
+			continue
+		}
+		deltas = append(deltas, fundDelta{
+			Name:                fund.Name,
+			NAVDelta:            floatDelta(fund.NAV, prev.NAV),
+			MTDDelta:            floatDelta(fund.MTD, prev.MTD),
+			FYTDDelta:           floatDelta(fund.FYTD, prev.FYTD),
+			CYTDDelta:           floatDelta(fund.CYTD, prev.CYTD),
+			SinceInceptionDelta: floatDelta(fund.SinceInception, prev.SinceInception),
+		})
+	}
+	return deltas
+}
+
+func floatDelta(curr, prev *float64) *float64 {
+	if curr == nil || prev == nil {
+		return nil
+	}
+	d := *curr - *prev
+	return &d
+}
+
+// publishFundsIngested enqueues a fund.ingested delivery for every active
+// webhook subscriber; it never blocks the caller on delivery.
+func (s *Server) publishFundsIngested(ctx context.Context, uploadDate time.Time, sha256Hex string, funds []Fund, previous map[string]Fund) {
+	event := fundIngestedEvent{
+		Event:      "fund.ingested",
+		UploadDate: uploadDate,
+		SHA256:     sha256Hex,
+		FundCount:  len(funds),
+		Funds:      funds,
+		Deltas:     buildFundDeltas(funds, previous),
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to marshal fund.ingested event", "error", err)
+		return
+	}
+
+	rows, err := s.backend.DB().QueryContext(ctx,
+		placeholderQuery(s.backend.Driver(), "SELECT id FROM webhooks WHERE active = ?"), true)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list active webhooks", "error", err)
+		return
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	for _, id := range ids {
+		_, err := s.backend.DB().ExecContext(ctx, placeholderQuery(s.backend.Driver(), `
+			INSERT INTO webhook_deliveries (webhook_id, payload, next_attempt_at, created_at)
+			VALUES (?, ?, ?, ?)
+		`), id, string(payload), time.Now().UTC().Format(time.RFC3339), time.Now().UTC().Format(time.RFC3339))
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to enqueue webhook delivery", "error", err, "webhook_id", id)
+		}
+	}
+}
+
+// deliverDue sends every delivery whose next_attempt_at has passed,
+// signing the payload like GitHub's X-Hub-Signature-256.
+func (d *webhookDispatcher) deliverDue(ctx context.Context) {
+	db := d.server.backend.DB()
+	driver := d.server.backend.Driver()
+
+	rows, err := db.QueryContext(ctx, placeholderQuery(driver, `
+		SELECT wd.id, wd.payload, wd.attempts, w.id, w.url, w.secret
+		FROM webhook_deliveries wd
+		JOIN webhooks w ON w.id = wd.webhook_id
+		WHERE wd.status = ? AND wd.next_attempt_at <= ?
+	`), webhookDeliveryPending, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		d.logger.ErrorContext(ctx, "failed to query pending webhook deliveries", "error", err)
+		return
+	}
+
+	type delivery struct {
+		id, webhookID     int64
+		payload, url, key string
+		attempts          int
+	}
+	var due []delivery
+	for rows.Next() {
+		var dl delivery
+		if err := rows.Scan(&dl.id, &dl.payload, &dl.attempts, &dl.webhookID, &dl.url, &dl.key); err != nil {
+			continue
+		}
+		due = append(due, dl)
+	}
+	rows.Close()
+
+	for _, dl := range due {
+		err := d.send(ctx, dl.url, dl.key, dl.payload)
+		if err == nil {
+			db.ExecContext(ctx, placeholderQuery(driver,
+				"UPDATE webhook_deliveries SET status = ? WHERE id = ?"), webhookDeliveryDelivered, dl.id)
+			db.ExecContext(ctx, placeholderQuery(driver,
+				"UPDATE webhooks SET last_status = 200, last_error = '' WHERE id = ?"), dl.webhookID)
+			continue
+		}
+
+		attempts := dl.attempts + 1
+		d.logger.WarnContext(ctx, "webhook delivery failed", "error", err, "webhook_id", dl.webhookID, "attempts", attempts)
+		db.ExecContext(ctx, placeholderQuery(driver,
+			"UPDATE webhooks SET last_error = ?, retries = retries + 1 WHERE id = ?"), err.Error(), dl.webhookID)
+
+		if attempts >= webhookMaxRetries {
+			db.ExecContext(ctx, placeholderQuery(driver,
+				"UPDATE webhook_deliveries SET status = ?, attempts = ?, last_error = ? WHERE id = ?"),
+				webhookDeliveryFailed, attempts, err.Error(), dl.id)
+			continue
+		}
+
+		backoff := webhookRetryBase * time.Duration(1<<uint(attempts))
+		nextAttempt := time.Now().UTC().Add(backoff).Format(time.RFC3339)
+		db.ExecContext(ctx, placeholderQuery(driver,
+			"UPDATE webhook_deliveries SET attempts = ?, next_attempt_at = ?, last_error = ? WHERE id = ?"),
+			attempts, nextAttempt, err.Error(), dl.id)
+	}
+}
+
+func (d *webhookDispatcher) send(ctx context.Context, url, secret, payload string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader([]byte(payload)))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Hub-Signature-256", "sha256="+signPayload(secret, payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// validateWebhookURL requires an absolute http(s) URL with a host, so a
+// registered subscriber can't be used to smuggle requests to a file://,
+// unix://, or schemeless internal path through the dispatcher.
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("webhook url must use http or https")
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("webhook url must include a host")
+	}
+	return nil
+}
+
+func signPayload(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// registerWebhookHandler registers a new fund.ingested subscriber.
+func (s *Server) registerWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Header.Get("X-API-Key") != s.config.APIKey {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		URL    string `json:"url"`
+		Secret string `json:"secret"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.URL == "" || req.Secret == "" {
+		http.Error(w, "'url' and 'secret' are required", http.StatusBadRequest)
+		return
+	}
+	if err := validateWebhookURL(req.URL); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	row := s.backend.DB().QueryRowContext(ctx, placeholderQuery(s.backend.Driver(), `
+		INSERT INTO webhooks (url, secret, active, created_at) VALUES (?, ?, ?, ?) RETURNING id
+	`), req.URL, req.Secret, true, time.Now().UTC().Format(time.RFC3339))
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		s.logger.ErrorContext(ctx, "failed to register webhook", "error", err)
+		http.Error(w, "Failed to register webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Webhook{ID: id, URL: req.URL, Active: true})
+}
@@ -0,0 +1,72 @@
+package main
+
+import "testing"
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestFloatDelta(t *testing.T) {
+	tests := []struct {
+		name       string
+		curr, prev *float64
+		want       *float64
+	}{
+		{name: "both present", curr: floatPtr(105.5), prev: floatPtr(100), want: floatPtr(5.5)},
+		{name: "curr nil", curr: nil, prev: floatPtr(100), want: nil},
+		{name: "prev nil", curr: floatPtr(100), prev: nil, want: nil},
+		{name: "both nil", curr: nil, prev: nil, want: nil},
+		{name: "no change", curr: floatPtr(50), prev: floatPtr(50), want: floatPtr(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := floatDelta(tt.curr, tt.prev)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("floatDelta() = %v, want nil", *got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("floatDelta() = nil, want %v", *tt.want)
+			}
+			if *got != *tt.want {
+				t.Fatalf("floatDelta() = %v, want %v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildFundDeltas(t *testing.T) {
+	funds := []Fund{
+		{Name: "Fund A", NAV: floatPtr(110), MTD: floatPtr(1.5)},
+		{Name: "Fund B", NAV: floatPtr(50)}, // no previous row
+	}
+	previous := map[string]Fund{
+		"Fund A": {NAV: floatPtr(100), MTD: floatPtr(1.0)},
+	}
+
+	deltas := buildFundDeltas(funds, previous)
+	if len(deltas) != 1 {
+		t.Fatalf("buildFundDeltas() returned %d deltas, want 1 (Fund B has no prior row)", len(deltas))
+	}
+
+	got := deltas[0]
+	if got.Name != "Fund A" {
+		t.Fatalf("deltas[0].Name = %q, want %q", got.Name, "Fund A")
+	}
+	if got.NAVDelta == nil || *got.NAVDelta != 10 {
+		t.Errorf("deltas[0].NAVDelta = %v, want 10", got.NAVDelta)
+	}
+	if got.MTDDelta == nil || *got.MTDDelta != 0.5 {
+		t.Errorf("deltas[0].MTDDelta = %v, want 0.5", got.MTDDelta)
+	}
+	if got.FYTDDelta != nil {
+		t.Errorf("deltas[0].FYTDDelta = %v, want nil (neither row had a value)", got.FYTDDelta)
+	}
+}
+
+func TestBuildFundDeltasEmpty(t *testing.T) {
+	if got := buildFundDeltas(nil, map[string]Fund{}); got != nil {
+		t.Fatalf("buildFundDeltas(nil, {}) = %v, want nil", got)
+	}
+}